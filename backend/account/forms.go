@@ -0,0 +1,7 @@
+package account
+
+const emailRegex = `^[^\s@]+@[^\s@]+\.[^\s@]+$`
+
+type ResetPasswordForm struct {
+	Email string `json:"email"`
+}
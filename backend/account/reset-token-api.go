@@ -3,15 +3,19 @@ package account
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"os"
 	"regexp"
+	"strconv"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/gotk/ctx"
 	"github.com/gotk/pg"
 
 	"github.com/rafael84/go-spa/backend/base"
+	"github.com/rafael84/go-spa/backend/context"
+	"github.com/rafael84/go-spa/backend/i18n"
 	"github.com/rafael84/go-spa/backend/mail"
 )
 
@@ -20,13 +24,30 @@ type ValidKey struct {
 	Key    string `json:"key"`
 }
 
+// resetPasswordRateLimit throttles requests for a new reset email; generous
+// enough for normal retries, tight enough to stop mailbomb abuse.
+var resetPasswordRateLimit = context.RateLimit{Requests: 5, Per: time.Minute}
+
+// keyGuessRateLimit throttles /validate-key and /complete, which both accept
+// a reset-token key, to make brute-forcing the key space infeasible.
+var keyGuessRateLimit = context.RateLimit{Requests: 10, Per: time.Minute}
+
+// tooManyRequests writes a 429, matching the status Endpoint.RateLimit
+// returns for endpoints wired through the Endpoint flow; these resources
+// are registered through ctx.Resource instead, so they check the limit and
+// respond themselves.
+func tooManyRequests(rw http.ResponseWriter) error {
+	http.Error(rw, "Too many requests, please try again later", http.StatusTooManyRequests)
+	return errors.New("too many requests")
+}
+
 func init() {
 	ctx.Resource("/account/reset-password", &ResetPasswordResource{}, true)
 	ctx.Resource("/account/reset-password/validate-key", &ValidateKeyResource{}, true)
 	ctx.Resource("/account/reset-password/complete", &CompleteResource{}, true)
 }
 
-func sendResetPasswordEmail(c *ctx.Context, user *User) {
+func sendResetPasswordEmail(c *ctx.Context, user *User, locale string) {
 	var body bytes.Buffer
 
 	resetTokenService := NewResetTokenService(c.Vars["db"].(*pg.Session))
@@ -37,14 +58,19 @@ func sendResetPasswordEmail(c *ctx.Context, user *User) {
 		return
 	}
 
+	// The link must carry the userId alongside the key: ValidateKeyResource
+	// and CompleteResource both require GetByKeyAndUserId to match, and the
+	// emailed link is the only channel the client has to learn that userId.
 	body.WriteString("Access this link: ")
-	body.WriteString("http://localhost:3000/#/reset-password/step2/")
+	body.WriteString("http://localhost:3000/#/")
+	body.WriteString(locale)
+	body.WriteString("/reset-password/step2/")
+	body.WriteString(strconv.FormatInt(user.Id.NullInt64.Int64, 10))
+	body.WriteString("/")
 	body.WriteString(resetToken.Key)
 
-	err = mail.NewGmailAccount(
-		os.Getenv("EMAIL_USERNAME"),
-		os.Getenv("EMAIL_PASSWORD"),
-	).Send(&mail.Message{
+	sender := c.Vars["mailSender"].(mail.Sender)
+	err = sender.Send(&mail.Message{
 		From:    "Go-SPA",
 		To:      []string{user.Email},
 		Subject: "Reset Password",
@@ -63,30 +89,33 @@ type ResetPasswordResource struct {
 }
 
 func (r *ResetPasswordResource) POST(c *ctx.Context, rw http.ResponseWriter, req *http.Request) error {
+	if !context.Allow(req, "reset-password", resetPasswordRateLimit) {
+		return tooManyRequests(rw)
+	}
+
 	// decode request data
 	var form ResetPasswordForm
 	err := json.NewDecoder(req.Body).Decode(&form)
 	if err != nil {
-		return ctx.BadRequest(rw, "Could not query user: %s", err)
+		return context.WriteError(rw, context.BadRequest("errors.request.invalid"))
 	}
 
 	// validate email address
 	if ok := regexp.MustCompile(emailRegex).MatchString(form.Email); !ok {
-		return ctx.BadRequest(rw, "Invalid email address")
+		return context.WriteError(rw, context.Validation("email", "errors.email.invalid"))
 	}
 
 	// create new user service
 	userService := NewUserService(r.DB(c))
 
-	// get user from database
-	var user *User
-	user, err = userService.GetByEmail(form.Email)
-	if err != nil {
-		return ctx.BadRequest(rw, "User not found")
+	// get user from database; an unknown email is not reported back to the
+	// caller, so this endpoint can't be used to enumerate accounts
+	user, err := userService.GetByEmail(form.Email)
+	if err == nil {
+		locale := i18n.DefaultCatalog.Match(req.Header.Get("Accept-Language"))
+		go sendResetPasswordEmail(c, user, locale)
 	}
 
-	go sendResetPasswordEmail(c, user)
-
 	return ctx.OK(rw, "Email sent")
 }
 
@@ -95,22 +124,22 @@ type ValidateKeyResource struct {
 }
 
 func (r *ValidateKeyResource) POST(c *ctx.Context, rw http.ResponseWriter, req *http.Request) error {
-	type ValidateKeyForm struct {
-		Key string `json:"key"`
+	if !context.Allow(req, "validate-key", keyGuessRateLimit) {
+		return tooManyRequests(rw)
 	}
 
 	// decode request data
-	var form ValidateKeyForm
+	var form ValidKey
 	err := json.NewDecoder(req.Body).Decode(&form)
 	if err != nil {
-		return ctx.BadRequest(rw, "Unable to validate key")
+		return context.WriteError(rw, context.BadRequest("errors.request.invalid"))
 	}
 
 	service := NewResetTokenService(r.DB(c))
 
-	resetToken, err := service.GetByKey(form.Key)
+	resetToken, err := service.GetByKeyAndUserId(form.Key, form.UserId)
 	if err != nil || !resetToken.Valid() {
-		return ctx.BadRequest(rw, "Invalid Key")
+		return context.WriteError(rw, context.Validation("key", "errors.resetToken.invalid"))
 	}
 
 	return ctx.OK(rw, ValidKey{resetToken.UserId, form.Key})
@@ -121,6 +150,10 @@ type CompleteResource struct {
 }
 
 func (r *CompleteResource) POST(c *ctx.Context, rw http.ResponseWriter, req *http.Request) error {
+	if !context.Allow(req, "complete", keyGuessRateLimit) {
+		return tooManyRequests(rw)
+	}
+
 	type ChangePasswordForm struct {
 		Password      string   `json:"password"`
 		PasswordAgain string   `json:"passwordAgain"`
@@ -131,45 +164,45 @@ func (r *CompleteResource) POST(c *ctx.Context, rw http.ResponseWriter, req *htt
 	var form ChangePasswordForm
 	err := json.NewDecoder(req.Body).Decode(&form)
 	if err != nil {
-		return ctx.BadRequest(rw, "Unable to change the password")
+		return context.WriteError(rw, context.BadRequest("errors.request.invalid"))
 	}
 
 	// validate the passwords
 	if form.Password != form.PasswordAgain {
-		return ctx.BadRequest(rw, "Passwords mismatch")
+		return context.WriteError(rw, context.Validation("passwordAgain", "errors.password.mismatch"))
 	}
 
-	// validate the key again
+	// validate the key again, bound to the same user that validated it
 	resetTokenService := NewResetTokenService(r.DB(c))
-	resetToken, err := resetTokenService.GetByKey(form.ValidKey.Key)
+	resetToken, err := resetTokenService.GetByKeyAndUserId(form.ValidKey.Key, form.ValidKey.UserId)
 	if err != nil || !resetToken.Valid() {
-		return ctx.BadRequest(rw, "Invalid Key")
+		return context.WriteError(rw, context.Validation("key", "errors.resetToken.invalid"))
 	}
 
 	// get user from db
 	userService := NewUserService(r.DB(c))
 	user, err := userService.GetById(resetToken.UserId)
 	if err != nil {
-		return ctx.InternalServerError(rw, "User not found")
+		return context.WriteError(rw, context.NotFound("errors.user.notFound"))
 	}
 
 	// encode user password
 	err = user.Password.Encode(form.Password)
 	if err != nil {
-		return ctx.InternalServerError(rw, "Could not change user password")
+		return context.WriteError(rw, context.InternalError("errors.password.changeFailed"))
 	}
 
 	// change user data in database
 	err = userService.Update(user)
 	if err != nil {
-		return ctx.InternalServerError(rw, "Could not change user password")
+		return context.WriteError(rw, context.InternalError("errors.password.changeFailed"))
 	}
 
-	// invalidate token
-	resetToken.State = ResetTokenInactive
-	err = resetTokenService.Update(resetToken)
+	// invalidate every outstanding reset token for this user, not just the
+	// one that was used, so other emailed links stop working too
+	err = resetTokenService.DeleteByUserId(user.Id.NullInt64.Int64)
 	if err != nil {
-		log.Errorf("Unable to invalidate token: %s", err)
+		log.Errorf("Unable to invalidate tokens: %s", err)
 	}
 
 	return ctx.OK(rw, user)
@@ -0,0 +1,84 @@
+package account
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/gotk/pg"
+)
+
+var errTokenUserMismatch = errors.New("reset token does not belong to user")
+
+type ResetTokenService struct {
+	db *pg.Session
+}
+
+func NewResetTokenService(db *pg.Session) *ResetTokenService {
+	return &ResetTokenService{db: db}
+}
+
+func (s *ResetTokenService) Create(userId int64) (*ResetToken, error) {
+	key, err := generateResetTokenKey()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &ResetToken{
+		UserId: userId,
+		Key:    key,
+		State:  ResetTokenActive,
+	}
+	if err := s.db.Insert(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *ResetTokenService) GetByKey(key string) (*ResetToken, error) {
+	token := &ResetToken{}
+	err := s.db.Model(token).Where("key = ?", key).Select()
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetByKeyAndUserId looks up a token by key and additionally requires it to
+// belong to userId, so a leaked key alone cannot be redeemed against a
+// different account.
+func (s *ResetTokenService) GetByKeyAndUserId(key string, userId int64) (*ResetToken, error) {
+	token, err := s.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !boundToUser(token, userId) {
+		return nil, errTokenUserMismatch
+	}
+	return token, nil
+}
+
+// boundToUser reports whether token was issued for userId.
+func boundToUser(token *ResetToken, userId int64) bool {
+	return token.UserId == userId
+}
+
+func (s *ResetTokenService) Update(token *ResetToken) error {
+	return s.db.Update(token)
+}
+
+// DeleteByUserId invalidates every outstanding reset token for userId, so a
+// successful reset (or a cleanup pass) can't be bypassed by a token issued
+// earlier in the same request chain.
+func (s *ResetTokenService) DeleteByUserId(userId int64) error {
+	_, err := s.db.Model(&ResetToken{}).Where("user_id = ?", userId).Delete()
+	return err
+}
+
+func generateResetTokenKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,20 @@
+package account
+
+const (
+	ResetTokenActive = iota
+	ResetTokenInactive
+)
+
+type ResetToken struct {
+	tableName struct{} `sql:"reset_tokens"`
+
+	Id     int64  `json:"id"`
+	UserId int64  `json:"userId"`
+	Key    string `json:"key"`
+	State  int    `json:"-"`
+}
+
+// Valid reports whether the token can still be redeemed.
+func (t *ResetToken) Valid() bool {
+	return t.State == ResetTokenActive
+}
@@ -0,0 +1,26 @@
+package account
+
+import "testing"
+
+func TestResetTokenValid(t *testing.T) {
+	active := &ResetToken{State: ResetTokenActive}
+	if !active.Valid() {
+		t.Error("expected an active token to be valid")
+	}
+
+	inactive := &ResetToken{State: ResetTokenInactive}
+	if inactive.Valid() {
+		t.Error("expected an inactive token to be invalid")
+	}
+}
+
+func TestBoundToUser(t *testing.T) {
+	token := &ResetToken{UserId: 42}
+
+	if !boundToUser(token, 42) {
+		t.Error("expected token to be bound to its own userId")
+	}
+	if boundToUser(token, 43) {
+		t.Error("expected token not to be bound to a different userId")
+	}
+}
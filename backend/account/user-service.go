@@ -0,0 +1,35 @@
+package account
+
+import (
+	"github.com/gotk/pg"
+)
+
+type UserService struct {
+	db *pg.Session
+}
+
+func NewUserService(db *pg.Session) *UserService {
+	return &UserService{db: db}
+}
+
+func (s *UserService) GetByEmail(email string) (*User, error) {
+	user := &User{}
+	err := s.db.Model(user).Where("email = ?", email).Select()
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) GetById(id int64) (*User, error) {
+	user := &User{}
+	err := s.db.Model(user).Where("id = ?", id).Select()
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserService) Update(user *User) error {
+	return s.db.Update(user)
+}
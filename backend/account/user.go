@@ -0,0 +1,63 @@
+package account
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// NullInt64 wraps sql.NullInt64 so models can marshal ids to JSON as plain
+// numbers while still tolerating NULL in the database.
+type NullInt64 struct {
+	sql.NullInt64
+}
+
+// MarshalJSON encodes a valid value as a plain number and a NULL value as
+// JSON null, rather than sql.NullInt64's {Int64, Valid} struct shape.
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Int64)
+}
+
+// UnmarshalJSON accepts a plain number or JSON null, the inverse of
+// MarshalJSON.
+func (n *NullInt64) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.Int64, n.Valid = 0, false
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Int64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Password is stored as a bcrypt hash; never marshaled back to the client.
+type Password string
+
+// Encode replaces the receiver with the bcrypt hash of plain.
+func (p *Password) Encode(plain string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	*p = Password(hash)
+	return nil
+}
+
+// Matches reports whether plain hashes to the same value as the receiver.
+func (p Password) Matches(plain string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(p), []byte(plain)) == nil
+}
+
+type User struct {
+	tableName struct{} `sql:"users"`
+
+	Id       NullInt64 `json:"id"`
+	Email    string    `json:"email"`
+	Password Password  `json:"-"`
+}
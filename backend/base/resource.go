@@ -0,0 +1,15 @@
+package base
+
+import (
+	"github.com/gotk/ctx"
+	"github.com/gotk/pg"
+)
+
+// Resource is embedded by API resources to share access to request-scoped
+// dependencies such as the database session.
+type Resource struct{}
+
+// DB returns the *pg.Session stored in the context for the current request.
+func (r *Resource) DB(c *ctx.Context) *pg.Session {
+	return c.Vars["db"].(*pg.Session)
+}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"crypto/rsa"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -9,16 +10,15 @@ import (
 	"github.com/codegangsta/negroni"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
-	"github.com/nicksnyder/go-i18n/i18n"
 )
 
 var (
-	verifyKey []byte
-	signKey   []byte
+	verifyKey *rsa.PublicKey
+	signKey   *rsa.PrivateKey
 	endpoints = []*Endpoint{}
 )
 
-type ContextHandler func(c *Context, rw http.ResponseWriter, req *http.Request) error
+type ContextHandler func(c *Context, rw http.ResponseWriter, req *http.Request) *Error
 
 type MethodHandlers map[string]ContextHandler
 
@@ -32,9 +32,10 @@ func (handlers *MethodHandlers) IsAllowed(req *http.Request) bool {
 }
 
 type Endpoint struct {
-	Public   bool
-	Path     string
-	Handlers MethodHandlers
+	Public    bool
+	Path      string
+	Handlers  MethodHandlers
+	RateLimit *RateLimit
 }
 
 func AddEndpoint(endpoint *Endpoint) {
@@ -58,8 +59,8 @@ type Context struct {
 	Router *mux.Router
 	Vars   map[string]interface{}
 
-	T     i18n.TranslateFunc
-	Token *jwt.Token
+	Token  *jwt.Token
+	Client string
 
 	middleware *jwtmiddleware.JWTMiddleware
 }
@@ -103,14 +104,23 @@ func SignToken(token *jwt.Token) (string, error) {
 }
 
 func LoadSecureKeys(privateKeyPath, publicKeyPath string) (err error) {
-	signKey, err = ioutil.ReadFile(privateKeyPath)
+	privateKeyBytes, err := ioutil.ReadFile(privateKeyPath)
 	if err != nil {
 		return fmt.Errorf("Error reading private key")
 	}
-	verifyKey, err = ioutil.ReadFile(publicKeyPath)
+	signKey, err = jwt.ParseRSAPrivateKeyFromPEM(privateKeyBytes)
+	if err != nil {
+		return fmt.Errorf("Error parsing private key: %s", err)
+	}
+
+	publicKeyBytes, err := ioutil.ReadFile(publicKeyPath)
 	if err != nil {
 		return fmt.Errorf("Error reading public key")
 	}
+	verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyBytes)
+	if err != nil {
+		return fmt.Errorf("Error parsing public key: %s", err)
+	}
 	return nil
 }
 
@@ -120,21 +130,33 @@ func newContextHandler(context *Context, endpoint *Endpoint) http.HandlerFunc {
 			http.Error(rw, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
+		if endpoint.RateLimit != nil && !Allow(req, endpoint.Path, *endpoint.RateLimit) {
+			http.Error(rw, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
 		if !endpoint.Public {
 			context.Token, _ = jwt.ParseFromRequest(
 				req, context.middleware.Options.ValidationKeyGetter,
 			)
+			context.Client = clientIdClaim(context.Token)
 		}
-		context.updateT(req)
-		err := endpoint.Handlers[req.Method](context, rw, req)
-		if err != nil {
-			http.Error(rw, err.Error(), http.StatusBadRequest)
+		if err := endpoint.Handlers[req.Method](context, rw, req); err != nil {
+			WriteError(rw, err)
 		}
 	}
 }
 
-func (c *Context) updateT(req *http.Request) {
-	acceptLang := req.Header.Get("Accept-Language")
-	defaultLang := "en-US"
-	c.T = i18n.MustTfunc(acceptLang, defaultLang)
+// clientIdClaim extracts the "client_id" claim from an OAuth2 access token,
+// if present, so handlers can tell which registered client a request came
+// through without re-parsing the token themselves.
+func clientIdClaim(token *jwt.Token) string {
+	if token == nil {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	clientId, _ := claims["client_id"].(string)
+	return clientId
 }
@@ -0,0 +1,57 @@
+package context
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error is a structured response returned by handlers instead of a
+// free-form string, so the SPA can switch on Code/Fields rather than
+// parsing error text, and messages stay translatable via i18n message keys.
+type Error struct {
+	Code    string            `json:"code"`
+	Status  int               `json:"-"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Validation builds a 422 error for a single invalid field, identified by
+// an i18n message key so the SPA can highlight and translate it.
+func Validation(field, msgKey string) *Error {
+	return &Error{
+		Code:    "validation_error",
+		Status:  http.StatusUnprocessableEntity,
+		Message: msgKey,
+		Fields:  map[string]string{field: msgKey},
+	}
+}
+
+// NotFound builds a 404 error identified by an i18n message key.
+func NotFound(msgKey string) *Error {
+	return &Error{Code: "not_found", Status: http.StatusNotFound, Message: msgKey}
+}
+
+// BadRequest builds a generic 400 error identified by an i18n message key.
+func BadRequest(msgKey string) *Error {
+	return &Error{Code: "bad_request", Status: http.StatusBadRequest, Message: msgKey}
+}
+
+// InternalError builds a generic 500 error identified by an i18n message key.
+func InternalError(msgKey string) *Error {
+	return &Error{Code: "internal_error", Status: http.StatusInternalServerError, Message: msgKey}
+}
+
+// WriteError JSON-encodes err to rw with its status code and returns it
+// unchanged, so callers on a response framework other than Endpoint (e.g.
+// account's resources) can still write a structured body and propagate the
+// error for logging.
+func WriteError(rw http.ResponseWriter, err *Error) *Error {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(err.Status)
+	json.NewEncoder(rw).Encode(err)
+	return err
+}
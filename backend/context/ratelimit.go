@@ -0,0 +1,76 @@
+package context
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a token-bucket limit: Requests tokens are available
+// per Per duration for a given key.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Store tracks token buckets keyed by an arbitrary string. The default is
+// in-memory; a Redis-backed Store can be swapped in later by replacing
+// DefaultStore without touching callers.
+type Store interface {
+	Allow(key string, limit RateLimit) bool
+}
+
+// DefaultStore is used by Allow and by Endpoint.RateLimit.
+var DefaultStore Store = NewMemoryStore()
+
+type bucket struct {
+	tokens  int
+	resetAt time.Time
+}
+
+// MemoryStore is a process-local Store. It's the default and is good enough
+// for a single instance; a multi-instance deployment should swap in a
+// Redis-backed Store instead.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryStore) Allow(key string, limit RateLimit) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	b, ok := s.buckets[key]
+	if !ok || now.After(b.resetAt) {
+		s.buckets[key] = &bucket{tokens: limit.Requests - 1, resetAt: now.Add(limit.Per)}
+		return true
+	}
+	if b.tokens <= 0 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Allow checks DefaultStore for a request from req's remote IP against
+// endpoint+limit, for use both by Endpoint.RateLimit and by handlers that
+// aren't wired through Endpoint (e.g. resources registered with other
+// routing).
+func Allow(req *http.Request, endpoint string, limit RateLimit) bool {
+	return DefaultStore.Allow(clientIP(req)+"|"+endpoint, limit)
+}
+
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,53 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreAllowsUpToLimit(t *testing.T) {
+	store := NewMemoryStore()
+	limit := RateLimit{Requests: 3, Per: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		if !store.Allow("key", limit) {
+			t.Fatalf("expected request %d to be allowed", i+1)
+		}
+	}
+	if store.Allow("key", limit) {
+		t.Error("expected request past the limit to be denied")
+	}
+}
+
+func TestMemoryStoreResetsAfterWindow(t *testing.T) {
+	store := NewMemoryStore()
+	limit := RateLimit{Requests: 1, Per: time.Millisecond}
+
+	if !store.Allow("key", limit) {
+		t.Fatal("expected the first request to be allowed")
+	}
+	if store.Allow("key", limit) {
+		t.Fatal("expected the second request within the window to be denied")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if !store.Allow("key", limit) {
+		t.Error("expected a request after the window to be allowed again")
+	}
+}
+
+func TestMemoryStoreIsolatesKeys(t *testing.T) {
+	store := NewMemoryStore()
+	limit := RateLimit{Requests: 1, Per: time.Minute}
+
+	if !store.Allow("a", limit) {
+		t.Fatal("expected the first request for key a to be allowed")
+	}
+	if !store.Allow("b", limit) {
+		t.Error("expected key b's bucket to be independent of key a's")
+	}
+	if store.Allow("a", limit) {
+		t.Error("expected key a to still be rate-limited")
+	}
+}
@@ -0,0 +1,167 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"golang.org/x/text/language"
+)
+
+const defaultLocale = "en-US"
+
+// Catalog holds the translation messages loaded for every supported locale
+// and negotiates the best match for a request's Accept-Language header.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string // locale -> key -> message
+	tags     []language.Tag
+	matcher  language.Matcher
+	warned   map[string]bool
+}
+
+// DefaultCatalog is used by packages (such as account) that need to
+// negotiate a locale without a request flowing through Context. Call
+// SetDefault once at startup, after Load.
+var DefaultCatalog = emptyCatalog()
+
+func emptyCatalog() *Catalog {
+	tag := language.MustParse(defaultLocale)
+	return &Catalog{
+		messages: map[string]map[string]string{defaultLocale: {}},
+		tags:     []language.Tag{tag},
+		matcher:  language.NewMatcher([]language.Tag{tag}),
+		warned:   map[string]bool{},
+	}
+}
+
+// SetDefault replaces DefaultCatalog.
+func SetDefault(c *Catalog) {
+	DefaultCatalog = c
+}
+
+// Load reads every "<locale>.json" file in dir into a Catalog, e.g.
+// "en-US.json", "pt-BR.json", "pt.json".
+func Load(dir string) (*Catalog, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return emptyCatalog(), nil
+	}
+
+	c := &Catalog{
+		messages: map[string]map[string]string{},
+		warned:   map[string]bool{},
+	}
+
+	for _, file := range files {
+		locale := strings.TrimSuffix(filepath.Base(file), ".json")
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: invalid catalog filename %q: %s", file, err)
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("i18n: invalid catalog %q: %s", file, err)
+		}
+
+		c.messages[tag.String()] = messages
+		c.tags = append(c.tags, tag)
+	}
+
+	// language.NewMatcher treats tags[0] as the fallback locale; Glob
+	// returns files in alphabetical order, which has no relation to which
+	// locale should be the default, so force defaultLocale to the front.
+	sortDefaultLocaleFirst(c.tags)
+
+	c.matcher = language.NewMatcher(c.tags)
+	return c, nil
+}
+
+// sortDefaultLocaleFirst moves defaultLocale to index 0 if it was loaded,
+// leaving the relative order of every other tag unchanged.
+func sortDefaultLocaleFirst(tags []language.Tag) {
+	for i, tag := range tags {
+		if tag.String() == defaultLocale {
+			copy(tags[1:i+1], tags[:i])
+			tags[0] = tag
+			return
+		}
+	}
+}
+
+// Locales returns every locale this Catalog has messages for.
+func (c *Catalog) Locales() []string {
+	locales := make([]string, len(c.tags))
+	for i, tag := range c.tags {
+		locales[i] = tag.String()
+	}
+	return locales
+}
+
+// Match negotiates acceptLanguage (an Accept-Language header value) against
+// the loaded catalogs, honoring quality values and falling back through
+// region (e.g. pt-BR -> pt) before settling on the first loaded locale.
+func (c *Catalog) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		_, index, _ := c.matcher.Match(language.Und)
+		return c.tags[index].String()
+	}
+	// the matched tag itself may carry the requested region as an extension
+	// (e.g. "pt-u-rg-brzzzz"), so return the loaded tag at its index instead
+	_, index, _ := c.matcher.Match(tags...)
+	return c.tags[index].String()
+}
+
+// T looks up key for locale. A missing key falls back to the key itself and
+// is logged once per (locale, key) rather than on every request.
+func (c *Catalog) T(locale, key string) string {
+	c.mu.RLock()
+	messages, ok := c.messages[locale]
+	c.mu.RUnlock()
+	if ok {
+		if msg, ok := messages[key]; ok {
+			return msg
+		}
+	}
+
+	c.warnMissing(locale, key)
+	return key
+}
+
+func (c *Catalog) warnMissing(locale, key string) {
+	warnKey := locale + "|" + key
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.warned[warnKey] {
+		return
+	}
+	c.warned[warnKey] = true
+	log.Warnf("i18n: missing key %q for locale %q", key, locale)
+}
+
+// Messages returns the full catalog for locale, or an empty map if locale
+// isn't loaded.
+func (c *Catalog) Messages(locale string) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	messages := c.messages[locale]
+	if messages == nil {
+		return map[string]string{}
+	}
+	return messages
+}
@@ -0,0 +1,36 @@
+package i18n
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/rafael84/go-spa/backend/context"
+)
+
+func init() {
+	context.AddEndpoint(&context.Endpoint{
+		Public: true,
+		Path:   "/i18n/{locale}",
+		Handlers: context.MethodHandlers{
+			"GET": serveCatalog,
+		},
+	})
+}
+
+// serveCatalog returns the compiled message catalog for a locale as JSON,
+// so the SPA can translate client-side. Nothing in this tree negotiates a
+// locale through context.Context (backend/account calls
+// i18n.DefaultCatalog.Match directly instead, since its handlers run
+// through gotk/ctx and never see a *context.Context), so context.Context
+// intentionally carries no Locale/T of its own.
+func serveCatalog(c *context.Context, rw http.ResponseWriter, req *http.Request) *context.Error {
+	locale := mux.Vars(req)["locale"]
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(DefaultCatalog.Messages(locale)); err != nil {
+		return context.InternalError("errors.i18n.encode_failed")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package mail
+
+// devSender is the process-wide MemorySender used when Transport is "memory"
+// and served back out through /dev/mail.
+var devSender = NewMemorySender()
+
+// Config selects and builds the Sender an application should use, so the
+// choice of transport lives in configuration rather than being baked into
+// account handlers.
+type Config struct {
+	Transport string // "smtp" (default), "mailgun", or "memory"
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPStartTLS bool
+
+	MailgunDomain string
+	MailgunAPIKey string
+
+	// DevToken gates /dev/mail (see dev.go). It must be provisioned
+	// out-of-band by an operator; a regular user's bearer token is never
+	// enough to read another user's captured mail.
+	DevToken string
+}
+
+func NewSender(cfg Config) Sender {
+	switch cfg.Transport {
+	case "mailgun":
+		return NewMailgunSender(cfg.MailgunDomain, cfg.MailgunAPIKey)
+	case "memory":
+		setDevToken(cfg.DevToken)
+		return devSender
+	default:
+		return NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPStartTLS)
+	}
+}
@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gotk/ctx"
+
+	"github.com/rafael84/go-spa/backend/base"
+)
+
+// devToken gates DevMailResource. Any authenticated user's bearer token
+// would otherwise be enough to read every other user's captured mail
+// (including their reset-password link), so this is a separate,
+// operator-provisioned secret set via Config.DevToken instead. Left empty,
+// the endpoint refuses every request.
+var devToken string
+
+func setDevToken(token string) {
+	devToken = token
+}
+
+func init() {
+	ctx.Resource("/dev/mail", &DevMailResource{}, false)
+}
+
+// DevMailResource exposes messages captured by the process-wide devSender,
+// so an operator can inspect mail sent in dev mode without a real mailbox.
+// Reachable only with a valid bearer token AND the X-Dev-Token header
+// matching the configured Config.DevToken.
+type DevMailResource struct {
+	*base.Resource
+}
+
+func (r *DevMailResource) GET(c *ctx.Context, rw http.ResponseWriter, req *http.Request) error {
+	given := req.Header.Get("X-Dev-Token")
+	if devToken == "" || subtle.ConstantTimeCompare([]byte(given), []byte(devToken)) != 1 {
+		http.Error(rw, "Not Found", http.StatusNotFound)
+		return errors.New("dev mail: missing or invalid X-Dev-Token")
+	}
+	return ctx.OK(rw, devSender.Messages())
+}
@@ -0,0 +1,16 @@
+package mail
+
+// Message is the payload handed to a Sender.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    []byte
+}
+
+// Sender delivers a Message through some transport. Handlers depend on this
+// interface rather than a concrete transport so tests and local development
+// can swap in MemorySender without touching handler code.
+type Sender interface {
+	Send(*Message) error
+}
@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MailgunSender delivers mail through Mailgun's HTTP API, representative of
+// the HTTP-API-style providers this package supports alongside SMTP.
+type MailgunSender struct {
+	Domain string
+	APIKey string
+
+	client *http.Client
+}
+
+func NewMailgunSender(domain, apiKey string) *MailgunSender {
+	return &MailgunSender{
+		Domain: domain,
+		APIKey: apiKey,
+		client: http.DefaultClient,
+	}
+}
+
+func (s *MailgunSender) Send(msg *Message) error {
+	form := url.Values{
+		"from":    {msg.From},
+		"to":      msg.To,
+		"subject": {msg.Subject},
+		"text":    {string(msg.Body)},
+	}
+
+	endpoint := "https://api.mailgun.net/v3/" + s.Domain + "/messages"
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("api", s.APIKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &mailgunError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+type mailgunError struct {
+	status int
+}
+
+func (e *mailgunError) Error() string {
+	return http.StatusText(e.status)
+}
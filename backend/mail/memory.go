@@ -0,0 +1,30 @@
+package mail
+
+import "sync"
+
+// MemorySender captures sent messages instead of delivering them, for use
+// in tests and local development so nothing ever reaches a real inbox.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages returns every message captured so far, oldest first.
+func (s *MemorySender) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := make([]*Message, len(s.messages))
+	copy(messages, s.messages)
+	return messages
+}
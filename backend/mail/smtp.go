@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+)
+
+// SMTPSender delivers mail through a generic SMTP server, with optional
+// STARTTLS, replacing the Gmail-only account this package used to hardcode.
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	StartTLS bool
+}
+
+func NewSMTPSender(host string, port int, username, password string, startTLS bool) *SMTPSender {
+	return &SMTPSender{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		StartTLS: startTLS,
+	}
+}
+
+func (s *SMTPSender) addr() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+func (s *SMTPSender) Send(msg *Message) error {
+	auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
+
+	var body []byte
+	body = append(body, []byte("Subject: "+msg.Subject+"\r\n\r\n")...)
+	body = append(body, msg.Body...)
+
+	if !s.StartTLS {
+		return smtp.SendMail(s.addr(), auth, msg.From, msg.To, body)
+	}
+
+	return s.sendWithStartTLS(auth, msg, body)
+}
+
+func (s *SMTPSender) sendWithStartTLS(auth smtp.Auth, msg *Message, body []byte) error {
+	client, err := smtp.Dial(s.addr())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+		return err
+	}
+	if err := client.Auth(auth); err != nil {
+		return err
+	}
+	if err := client.Mail(msg.From); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,39 @@
+package oauth2
+
+import (
+	"github.com/gotk/pg"
+)
+
+// ClientRegistration describes an OAuth2 client allowed to use the
+// authorization-code flow against this server.
+type ClientRegistration struct {
+	tableName struct{} `sql:"oauth2_clients"`
+
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"-"`
+	Name         string `json:"name"`
+	RedirectURI  string `json:"redirectUri"`
+}
+
+type ClientService struct {
+	db *pg.Session
+}
+
+func NewClientService(db *pg.Session) *ClientService {
+	return &ClientService{db: db}
+}
+
+func (s *ClientService) GetByClientId(clientId string) (*ClientRegistration, error) {
+	client := &ClientRegistration{}
+	err := s.db.Model(client).Where("client_id = ?", clientId).Select()
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// ValidateRedirectURI reports whether redirectURI is the one registered for
+// this client, as required before issuing or redeeming an authorization code.
+func (c *ClientRegistration) ValidateRedirectURI(redirectURI string) bool {
+	return c.RedirectURI == redirectURI
+}
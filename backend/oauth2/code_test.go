@@ -0,0 +1,39 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func challengeFor(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestAuthorizationCodeVerifyChallenge(t *testing.T) {
+	authCode := &AuthorizationCode{CodeChallenge: challengeFor("s3cr3t-verifier")}
+
+	if !authCode.VerifyChallenge("s3cr3t-verifier") {
+		t.Error("expected the matching verifier to pass")
+	}
+	if authCode.VerifyChallenge("wrong-verifier") {
+		t.Error("expected a mismatched verifier to fail")
+	}
+	if authCode.VerifyChallenge("") {
+		t.Error("expected an empty verifier to fail")
+	}
+}
+
+func TestAuthorizationCodeExpired(t *testing.T) {
+	fresh := &AuthorizationCode{ExpiresAt: time.Now().Add(authorizationCodeTTL)}
+	if fresh.expired() {
+		t.Error("expected a freshly issued code not to be expired")
+	}
+
+	stale := &AuthorizationCode{ExpiresAt: time.Now().Add(-time.Second)}
+	if !stale.expired() {
+		t.Error("expected a code past its ExpiresAt to be expired")
+	}
+}
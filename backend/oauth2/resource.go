@@ -0,0 +1,247 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gotk/pg"
+
+	"github.com/rafael84/go-spa/backend/context"
+)
+
+// tokenRateLimit bounds how fast a single IP can guess authorization codes,
+// refresh tokens, or PKCE verifiers against /oauth2/token and /introspect.
+var tokenRateLimit = &context.RateLimit{Requests: 20, Per: time.Minute}
+
+func init() {
+	context.AddEndpoint(&context.Endpoint{
+		Path: "/oauth2/authorize",
+		Handlers: context.MethodHandlers{
+			"POST": authorize,
+		},
+	})
+	context.AddEndpoint(&context.Endpoint{
+		Public:    true,
+		Path:      "/oauth2/token",
+		RateLimit: tokenRateLimit,
+		Handlers: context.MethodHandlers{
+			"POST": token,
+		},
+	})
+	context.AddEndpoint(&context.Endpoint{
+		Public:    true,
+		Path:      "/oauth2/introspect",
+		RateLimit: tokenRateLimit,
+		Handlers: context.MethodHandlers{
+			"POST": introspect,
+		},
+	})
+	context.AddEndpoint(&context.Endpoint{
+		Public: true,
+		Path:   "/oauth2/revoke",
+		Handlers: context.MethodHandlers{
+			"POST": revoke,
+		},
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) error {
+	rw.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(rw).Encode(v)
+}
+
+func db(c *context.Context) *pg.Session {
+	return c.Vars["db"].(*pg.Session)
+}
+
+// authorize issues a single-use authorization code for the already
+// authenticated user (via the standard JWT middleware), scoped to a client
+// and bound to a PKCE S256 challenge.
+func authorize(c *context.Context, rw http.ResponseWriter, req *http.Request) *context.Error {
+	var form struct {
+		ResponseType        string `json:"responseType"`
+		ClientId            string `json:"clientId"`
+		RedirectURI         string `json:"redirectUri"`
+		CodeChallenge       string `json:"codeChallenge"`
+		CodeChallengeMethod string `json:"codeChallengeMethod"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&form); err != nil {
+		return context.BadRequest("errors.oauth2.malformed_request")
+	}
+
+	if form.ResponseType != "code" {
+		return context.Validation("responseType", "errors.oauth2.unsupported_response_type")
+	}
+	if form.CodeChallengeMethod != "S256" {
+		return context.Validation("codeChallengeMethod", "errors.oauth2.unsupported_challenge_method")
+	}
+
+	clientService := NewClientService(db(c))
+	client, err := clientService.GetByClientId(form.ClientId)
+	if err != nil {
+		return context.Validation("clientId", "errors.oauth2.invalid_client")
+	}
+	if !client.ValidateRedirectURI(form.RedirectURI) {
+		return context.Validation("redirectUri", "errors.oauth2.redirect_uri_mismatch")
+	}
+
+	userId, err := tokenUserId(c)
+	if err != nil {
+		return context.BadRequest("errors.oauth2.invalid_token")
+	}
+
+	codeService := NewAuthorizationCodeService(db(c))
+	authCode, err := codeService.Create(client.ClientId, form.RedirectURI, form.CodeChallenge, userId)
+	if err != nil {
+		return context.InternalError("errors.oauth2.code_create_failed")
+	}
+
+	if err := writeJSON(rw, map[string]string{"code": authCode.Code}); err != nil {
+		return context.InternalError("errors.oauth2.encode_failed")
+	}
+	return nil
+}
+
+// token implements the authorization_code and refresh_token grants.
+func token(c *context.Context, rw http.ResponseWriter, req *http.Request) *context.Error {
+	var form struct {
+		GrantType    string `json:"grantType"`
+		Code         string `json:"code"`
+		ClientId     string `json:"clientId"`
+		RedirectURI  string `json:"redirectUri"`
+		CodeVerifier string `json:"codeVerifier"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&form); err != nil {
+		return context.BadRequest("errors.oauth2.malformed_request")
+	}
+
+	codeService := NewAuthorizationCodeService(db(c))
+	refreshTokenService := NewRefreshTokenService(db(c))
+
+	var userId int64
+
+	switch form.GrantType {
+	case "authorization_code":
+		authCode, err := codeService.Redeem(form.Code, form.ClientId, form.RedirectURI)
+		if err != nil {
+			return context.Validation("code", "errors.oauth2.invalid_grant")
+		}
+		if !authCode.VerifyChallenge(form.CodeVerifier) {
+			return context.Validation("codeVerifier", "errors.oauth2.invalid_grant")
+		}
+		userId = authCode.UserId
+
+	case "refresh_token":
+		existing, err := refreshTokenService.GetByToken(form.RefreshToken)
+		if err != nil || existing.ClientId != form.ClientId {
+			return context.Validation("refreshToken", "errors.oauth2.invalid_grant")
+		}
+		if err := refreshTokenService.Revoke(existing); err != nil {
+			return context.InternalError("errors.oauth2.token_revoke_failed")
+		}
+		userId = existing.UserId
+
+	default:
+		return context.Validation("grantType", "errors.oauth2.unsupported_grant_type")
+	}
+
+	accessToken, err := newAccessToken(form.ClientId, userId)
+	if err != nil {
+		return context.InternalError("errors.oauth2.token_sign_failed")
+	}
+	refreshToken, err := refreshTokenService.Create(form.ClientId, userId)
+	if err != nil {
+		return context.InternalError("errors.oauth2.token_create_failed")
+	}
+
+	if err := writeJSON(rw, map[string]interface{}{
+		"accessToken":  accessToken,
+		"refreshToken": refreshToken.Token,
+		"tokenType":    "Bearer",
+		"expiresIn":    int(accessTokenTTL.Seconds()),
+	}); err != nil {
+		return context.InternalError("errors.oauth2.encode_failed")
+	}
+	return nil
+}
+
+// introspect reports whether a refresh token is still active, per RFC 7662.
+func introspect(c *context.Context, rw http.ResponseWriter, req *http.Request) *context.Error {
+	var form struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&form); err != nil {
+		return context.BadRequest("errors.oauth2.malformed_request")
+	}
+
+	refreshToken, err := NewRefreshTokenService(db(c)).GetByToken(form.Token)
+	if err != nil {
+		if err := writeJSON(rw, map[string]bool{"active": false}); err != nil {
+			return context.InternalError("errors.oauth2.encode_failed")
+		}
+		return nil
+	}
+
+	if err := writeJSON(rw, map[string]interface{}{
+		"active":   true,
+		"clientId": refreshToken.ClientId,
+		"sub":      strconv.FormatInt(refreshToken.UserId, 10),
+	}); err != nil {
+		return context.InternalError("errors.oauth2.encode_failed")
+	}
+	return nil
+}
+
+// revoke invalidates a refresh token so it can no longer be exchanged for
+// new access tokens.
+func revoke(c *context.Context, rw http.ResponseWriter, req *http.Request) *context.Error {
+	var form struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&form); err != nil {
+		return context.BadRequest("errors.oauth2.malformed_request")
+	}
+
+	refreshToken, err := NewRefreshTokenService(db(c)).GetByToken(form.Token)
+	if err != nil {
+		// already inactive: revocation of an unknown token is a no-op per RFC 7009
+		if err := writeJSON(rw, map[string]bool{"revoked": true}); err != nil {
+			return context.InternalError("errors.oauth2.encode_failed")
+		}
+		return nil
+	}
+
+	if err := NewRefreshTokenService(db(c)).Revoke(refreshToken); err != nil {
+		return context.InternalError("errors.oauth2.token_revoke_failed")
+	}
+
+	if err := writeJSON(rw, map[string]bool{"revoked": true}); err != nil {
+		return context.InternalError("errors.oauth2.encode_failed")
+	}
+	return nil
+}
+
+// tokenUserId reads the "sub" claim set by the existing JWT middleware on
+// the user's own bearer token (distinct from the OAuth2 access tokens this
+// package issues).
+func tokenUserId(c *context.Context) (int64, error) {
+	if c.Token == nil {
+		return 0, errInvalidToken
+	}
+	claims, ok := c.Token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, errInvalidToken
+	}
+	switch sub := claims["sub"].(type) {
+	case float64:
+		return int64(sub), nil
+	case string:
+		return strconv.ParseInt(sub, 10, 64)
+	default:
+		return 0, errInvalidToken
+	}
+}
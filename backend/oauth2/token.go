@@ -0,0 +1,102 @@
+package oauth2
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gotk/pg"
+
+	"github.com/rafael84/go-spa/backend/context"
+)
+
+const (
+	accessTokenTTL  = 1 * time.Hour
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	errInvalidGrant = errors.New("invalid or expired grant")
+	errInvalidToken = errors.New("invalid or revoked token")
+)
+
+// RefreshToken is an opaque, revocable token that can be exchanged for a new
+// access token without the user re-authenticating.
+type RefreshToken struct {
+	tableName struct{} `sql:"oauth2_refresh_tokens"`
+
+	Token     string    `json:"-"`
+	ClientId  string    `json:"-"`
+	UserId    int64     `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+	Revoked   bool      `json:"-"`
+}
+
+func (t *RefreshToken) valid() bool {
+	return !t.Revoked && time.Now().Before(t.ExpiresAt)
+}
+
+type RefreshTokenService struct {
+	db *pg.Session
+}
+
+func NewRefreshTokenService(db *pg.Session) *RefreshTokenService {
+	return &RefreshTokenService{db: db}
+}
+
+func (s *RefreshTokenService) Create(clientId string, userId int64) (*RefreshToken, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := &RefreshToken{
+		Token:     token,
+		ClientId:  clientId,
+		UserId:    userId,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+	if err := s.db.Insert(refreshToken); err != nil {
+		return nil, err
+	}
+	return refreshToken, nil
+}
+
+func (s *RefreshTokenService) GetByToken(token string) (*RefreshToken, error) {
+	refreshToken := &RefreshToken{}
+	err := s.db.Model(refreshToken).Where("token = ?", token).Select()
+	if err != nil {
+		return nil, err
+	}
+	if !refreshToken.valid() {
+		return nil, errInvalidToken
+	}
+	return refreshToken, nil
+}
+
+func (s *RefreshTokenService) Revoke(token *RefreshToken) error {
+	token.Revoked = true
+	return s.db.Update(token)
+}
+
+// newAccessToken signs a short-lived JWT access token carrying the client
+// and user the token was issued to, using the server's existing signing key.
+func newAccessToken(clientId string, userId int64) (string, error) {
+	claims := jwt.MapClaims{
+		"client_id": clientId,
+		"sub":       userId,
+		"exp":       time.Now().Add(accessTokenTTL).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	return context.SignToken(token)
+}
+
+func generateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
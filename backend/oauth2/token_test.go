@@ -0,0 +1,51 @@
+package oauth2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/rafael84/go-spa/backend/context"
+)
+
+func TestRefreshTokenValid(t *testing.T) {
+	fresh := &RefreshToken{ExpiresAt: time.Now().Add(refreshTokenTTL)}
+	if !fresh.valid() {
+		t.Error("expected a freshly issued refresh token to be valid")
+	}
+
+	expired := &RefreshToken{ExpiresAt: time.Now().Add(-time.Second)}
+	if expired.valid() {
+		t.Error("expected an expired refresh token to be invalid")
+	}
+
+	revoked := &RefreshToken{ExpiresAt: time.Now().Add(refreshTokenTTL), Revoked: true}
+	if revoked.valid() {
+		t.Error("expected a revoked refresh token to be invalid, even if not yet expired")
+	}
+}
+
+func TestTokenUserId(t *testing.T) {
+	c := &context.Context{Token: &jwt.Token{Claims: jwt.MapClaims{"sub": float64(42)}}}
+	userId, err := tokenUserId(c)
+	if err != nil || userId != 42 {
+		t.Errorf("expected sub 42 from a numeric claim, got %d, %v", userId, err)
+	}
+
+	c = &context.Context{Token: &jwt.Token{Claims: jwt.MapClaims{"sub": "42"}}}
+	userId, err = tokenUserId(c)
+	if err != nil || userId != 42 {
+		t.Errorf("expected sub 42 from a string claim, got %d, %v", userId, err)
+	}
+
+	c = &context.Context{}
+	if _, err := tokenUserId(c); err == nil {
+		t.Error("expected an error when the context has no token")
+	}
+
+	c = &context.Context{Token: &jwt.Token{Claims: jwt.MapClaims{"sub": true}}}
+	if _, err := tokenUserId(c); err == nil {
+		t.Error("expected an error for a claim of an unsupported type")
+	}
+}